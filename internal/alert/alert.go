@@ -0,0 +1,301 @@
+// Package alert evaluates AlertRules against live probe samples and
+// notifies pluggable sinks when a rule's state changes.
+package alert
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+	"github.com/nenad/pinger/internal/ping"
+)
+
+// State is whether a rule is currently firing.
+type State string
+
+const (
+	StateOK     State = "ok"
+	StateFiring State = "firing"
+)
+
+// Event describes a rule transitioning between OK and firing.
+type Event struct {
+	Target     string
+	Rule       config.AlertRule
+	State      State
+	Since      time.Time
+	LatencyP95 time.Duration
+	Loss       float64
+}
+
+// Sink receives alert Events. Notify must not block for long, since it
+// runs on the Engine's evaluation goroutine.
+type Sink interface {
+	Notify(Event)
+}
+
+// statsWindowCapacity bounds ruleState.statsWindow, the sample history
+// backing an Event's reported LatencyP95/Loss. It's independent of a
+// rule's own detection window (st.window), which for e.g. a "down" rule
+// is as small as a single sample and would otherwise make every event
+// report a degenerate 0%/100% loss and a one-point p95.
+const statsWindowCapacity = 20
+
+// ruleState tracks one (rule, target) pair's sliding window, consecutive
+// failure count, and current firing state.
+type ruleState struct {
+	rule                config.AlertRule
+	firing              bool
+	since               time.Time
+	lastFired           time.Time
+	consecutiveFailures int
+	window              []ping.Sample
+	statsWindow         []ping.Sample
+}
+
+// Engine evaluates a set of AlertRules against every sample it's fed and
+// notifies sinks on state transitions.
+type Engine struct {
+	mu    sync.Mutex
+	rules []config.AlertRule
+	sinks []Sink
+	state map[string]map[string]*ruleState // rule ID -> target ID -> state
+}
+
+// NewEngine builds an Engine evaluating rules and notifying sinks.
+func NewEngine(rules []config.AlertRule, sinks ...Sink) *Engine {
+	return &Engine{
+		rules: rules,
+		sinks: sinks,
+		state: make(map[string]map[string]*ruleState),
+	}
+}
+
+// NewEngineFromConfig builds an Engine using cfg's configured rules and
+// sinks.
+func NewEngineFromConfig(cfg *config.Config) *Engine {
+	var sinks []Sink
+	for _, sc := range cfg.AlertSinks {
+		switch sc.Kind {
+		case config.AlertSinkNotification:
+			sinks = append(sinks, NotificationSink{})
+		case config.AlertSinkWebhook:
+			sinks = append(sinks, NewWebhookSink(sc.URL, sc.Secret))
+		case config.AlertSinkLog:
+			sinks = append(sinks, LogSink{})
+		}
+	}
+	return NewEngine(cfg.Alerts, sinks...)
+}
+
+// AddRule registers an additional rule, effective immediately.
+func (e *Engine) AddRule(rule config.AlertRule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = append(e.rules, rule)
+}
+
+// Firing reports whether any rule is currently in the firing state, for
+// the menubar icon badge.
+func (e *Engine) Firing() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, byTarget := range e.state {
+		for _, st := range byTarget {
+			if st.firing {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Run consumes results until the channel is closed, evaluating every rule
+// against every sample. Intended to run in its own goroutine.
+func (e *Engine) Run(results <-chan ping.TargetSample) {
+	for ts := range results {
+		e.handle(ts)
+	}
+}
+
+// handle updates rule state for ts under e.mu, then delivers any
+// resulting events to the sinks after releasing the lock. A slow sink
+// therefore doesn't block Firing, which shares e.mu with the evaluation
+// goroutine, but it still delays evaluation of the next sample, since
+// delivery runs on that same goroutine.
+func (e *Engine) handle(ts ping.TargetSample) {
+	e.mu.Lock()
+	var events []Event
+	for _, rule := range e.rules {
+		if rule.Target != "" && rule.Target != ts.TargetID {
+			continue
+		}
+
+		byTarget, ok := e.state[rule.ID]
+		if !ok {
+			byTarget = make(map[string]*ruleState)
+			e.state[rule.ID] = byTarget
+		}
+		st, ok := byTarget[ts.TargetID]
+		if !ok {
+			st = &ruleState{rule: rule}
+			byTarget[ts.TargetID] = st
+		}
+
+		if ev, ok := e.evaluate(st, ts); ok {
+			events = append(events, ev)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, ev := range events {
+		e.deliver(ev)
+	}
+}
+
+func (e *Engine) evaluate(st *ruleState, ts ping.TargetSample) (Event, bool) {
+	pushSample(&st.statsWindow, ts.Sample, statsWindowCapacity)
+
+	switch st.rule.Kind {
+	case config.AlertRuleLatencyAbove:
+		return e.evaluateLatencyAbove(st, ts)
+	case config.AlertRuleLossAbove:
+		return e.evaluateLossAbove(st, ts)
+	case config.AlertRuleDown:
+		return e.evaluateDown(st, ts)
+	}
+	return Event{}, false
+}
+
+func (e *Engine) evaluateLatencyAbove(st *ruleState, ts ping.TargetSample) (Event, bool) {
+	capacity := st.rule.Samples
+	if capacity <= 0 {
+		capacity = 1
+	}
+	pushSample(&st.window, ts.Sample, capacity)
+	if len(st.window) < capacity {
+		return Event{}, false
+	}
+
+	sustained := true
+	cleared := true
+	for _, s := range st.window {
+		if s.Failed || s.Latency.Microseconds() < int64(st.rule.TriggerLatencyUS) {
+			sustained = false
+		}
+		if s.Failed || s.Latency.Microseconds() > int64(st.rule.ClearLatencyUS) {
+			cleared = false
+		}
+	}
+	return e.transition(st, ts.TargetID, sustained, cleared)
+}
+
+func (e *Engine) evaluateLossAbove(st *ruleState, ts ping.TargetSample) (Event, bool) {
+	capacity := st.rule.Samples
+	if capacity <= 0 {
+		capacity = 20
+	}
+	pushSample(&st.window, ts.Sample, capacity)
+	if len(st.window) < capacity {
+		return Event{}, false
+	}
+
+	var failures int
+	for _, s := range st.window {
+		if s.Failed {
+			failures++
+		}
+	}
+	loss := float64(failures) / float64(len(st.window)) * 100
+	return e.transition(st, ts.TargetID, loss >= st.rule.TriggerLossPct, loss <= st.rule.ClearLossPct)
+}
+
+func (e *Engine) evaluateDown(st *ruleState, ts ping.TargetSample) (Event, bool) {
+	pushSample(&st.window, ts.Sample, 1)
+	if ts.Sample.Failed {
+		st.consecutiveFailures++
+	} else {
+		st.consecutiveFailures = 0
+	}
+
+	k := st.rule.ConsecutiveFailures
+	if k <= 0 {
+		k = 3
+	}
+	return e.transition(st, ts.TargetID, st.consecutiveFailures >= k, st.consecutiveFailures == 0)
+}
+
+func pushSample(window *[]ping.Sample, sample ping.Sample, capacity int) {
+	*window = append(*window, sample)
+	if len(*window) > capacity {
+		*window = (*window)[len(*window)-capacity:]
+	}
+}
+
+// transition applies hysteresis and cooldown, reporting an Event to
+// deliver to sinks on an actual state change.
+func (e *Engine) transition(st *ruleState, targetID string, shouldFire, shouldClear bool) (Event, bool) {
+	now := time.Now()
+	switch {
+	case !st.firing && shouldFire:
+		if !st.lastFired.IsZero() && now.Sub(st.lastFired) < st.rule.Cooldown {
+			return Event{}, false
+		}
+		st.firing = true
+		st.since = now
+		st.lastFired = now
+		return e.event(targetID, st, StateFiring), true
+	case st.firing && shouldClear:
+		st.firing = false
+		st.since = now
+		return e.event(targetID, st, StateOK), true
+	}
+	return Event{}, false
+}
+
+func (e *Engine) event(targetID string, st *ruleState, state State) Event {
+	p95, loss := windowStats(st.statsWindow)
+	return Event{
+		Target:     targetID,
+		Rule:       st.rule,
+		State:      state,
+		Since:      st.since,
+		LatencyP95: p95,
+		Loss:       loss,
+	}
+}
+
+// deliver notifies every sink of ev. Called without e.mu held, since
+// sinks may block (see the Sink doc comment).
+func (e *Engine) deliver(ev Event) {
+	for _, sink := range e.sinks {
+		sink.Notify(ev)
+	}
+}
+
+// windowStats computes the p95 latency and loss percentage across window,
+// a small local slice, so event doesn't need a History reference.
+func windowStats(window []ping.Sample) (p95 time.Duration, loss float64) {
+	if len(window) == 0 {
+		return 0, 0
+	}
+
+	var failures int
+	latenciesUS := make([]int64, 0, len(window))
+	for _, s := range window {
+		if s.Failed {
+			failures++
+			continue
+		}
+		latenciesUS = append(latenciesUS, s.Latency.Microseconds())
+	}
+
+	loss = float64(failures) / float64(len(window)) * 100
+	if len(latenciesUS) > 0 {
+		sort.Slice(latenciesUS, func(i, j int) bool { return latenciesUS[i] < latenciesUS[j] })
+		idx := int(0.95 * float64(len(latenciesUS)-1))
+		p95 = time.Duration(latenciesUS[idx]) * time.Microsecond
+	}
+	return p95, loss
+}
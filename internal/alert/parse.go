@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+// ParseRuleSpec parses a one-line rule description, the format the
+// Alerts... dialog in ui.TrayApp accepts:
+//
+//	latency_above <trigger_us> <clear_us> <samples>
+//	loss_above <trigger_pct> <clear_pct> <samples>
+//	down <consecutive_failures>
+//
+// The resulting rule applies to every target and uses a one-minute
+// cooldown.
+func ParseRuleSpec(spec string) (config.AlertRule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return config.AlertRule{}, fmt.Errorf("empty rule")
+	}
+
+	rule := config.AlertRule{ID: newRuleID(), Cooldown: time.Minute}
+	switch config.AlertRuleKind(fields[0]) {
+	case config.AlertRuleLatencyAbove:
+		if len(fields) != 4 {
+			return config.AlertRule{}, fmt.Errorf("usage: latency_above <trigger_us> <clear_us> <samples>")
+		}
+		rule.Kind = config.AlertRuleLatencyAbove
+		rule.TriggerLatencyUS = atoi(fields[1])
+		rule.ClearLatencyUS = atoi(fields[2])
+		rule.Samples = atoi(fields[3])
+
+	case config.AlertRuleLossAbove:
+		if len(fields) != 4 {
+			return config.AlertRule{}, fmt.Errorf("usage: loss_above <trigger_pct> <clear_pct> <samples>")
+		}
+		rule.Kind = config.AlertRuleLossAbove
+		rule.TriggerLossPct = atof(fields[1])
+		rule.ClearLossPct = atof(fields[2])
+		rule.Samples = atoi(fields[3])
+
+	case config.AlertRuleDown:
+		if len(fields) != 2 {
+			return config.AlertRule{}, fmt.Errorf("usage: down <consecutive_failures>")
+		}
+		rule.Kind = config.AlertRuleDown
+		rule.ConsecutiveFailures = atoi(fields[1])
+
+	default:
+		return config.AlertRule{}, fmt.Errorf("unknown rule kind %q", fields[0])
+	}
+
+	return rule, nil
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func atof(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func newRuleID() string {
+	return fmt.Sprintf("rule-%d", time.Now().UnixNano())
+}
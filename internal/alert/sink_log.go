@@ -0,0 +1,11 @@
+package alert
+
+import "log"
+
+// LogSink writes alert events to the standard logger.
+type LogSink struct{}
+
+func (LogSink) Notify(ev Event) {
+	log.Printf("alert: target=%s rule=%s state=%s p95=%s loss=%.1f%%",
+		ev.Target, ev.Rule.Kind, ev.State, ev.LatencyP95, ev.Loss)
+}
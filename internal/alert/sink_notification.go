@@ -0,0 +1,21 @@
+package alert
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// NotificationSink delivers alert events as native macOS notifications,
+// the same osascript pattern TrayApp uses for its own notifications.
+type NotificationSink struct{}
+
+func (NotificationSink) Notify(ev Event) {
+	title := fmt.Sprintf("%s: %s", ev.Target, ev.Rule.Kind)
+	message := "Cleared"
+	if ev.State == StateFiring {
+		message = fmt.Sprintf("Firing — p95 %dms, loss %.1f%%", ev.LatencyP95.Milliseconds(), ev.Loss)
+	}
+
+	script := fmt.Sprintf(`display notification "%s" with title "%s"`, message, title)
+	_ = exec.Command("osascript", "-e", script).Run()
+}
@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// WebhookSink POSTs alert events as JSON to a configured URL, signing the
+// body with HMAC-SHA256 when a secret is configured.
+type WebhookSink struct {
+	URL    string
+	Secret string
+
+	client *http.Client
+}
+
+// NewWebhookSink returns a WebhookSink posting to url, signed with secret
+// when non-empty.
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{URL: url, Secret: secret, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+type webhookPayload struct {
+	Target     string    `json:"target"`
+	Rule       string    `json:"rule"`
+	State      State     `json:"state"`
+	Since      time.Time `json:"since"`
+	LatencyP95 int64     `json:"latency_p95"`
+	Loss       float64   `json:"loss"`
+}
+
+func (w *WebhookSink) Notify(ev Event) {
+	body, err := json.Marshal(webhookPayload{
+		Target:     ev.Target,
+		Rule:       string(ev.Rule.Kind),
+		State:      ev.State,
+		Since:      ev.Since,
+		LatencyP95: ev.LatencyP95.Milliseconds(),
+		Loss:       ev.Loss,
+	})
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set("X-Pinger-Signature", w.sign(body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+func (w *WebhookSink) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -2,28 +2,240 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type ProbeMode string
 
 const (
 	ProbeModeICMP ProbeMode = "ICMP"
+	ProbeModeTCP  ProbeMode = "TCP"
 	ProbeModeHTTP ProbeMode = "HTTP"
+	ProbeModeTLS  ProbeMode = "TLS"
+	ProbeModeDNS  ProbeMode = "DNS"
 )
 
+var validProbeModes = map[ProbeMode]bool{
+	ProbeModeICMP: true,
+	ProbeModeTCP:  true,
+	ProbeModeHTTP: true,
+	ProbeModeTLS:  true,
+	ProbeModeDNS:  true,
+}
+
+// Valid reports whether m is one of the known probe modes.
+func (m ProbeMode) Valid() bool {
+	return validProbeModes[m]
+}
+
+// ProbeConfig holds the fields needed to construct a probe for a given
+// mode. Only the fields relevant to Mode are populated; the rest are left
+// zero-valued and ignored by that probe's factory.
+type ProbeConfig struct {
+	Mode ProbeMode `json:"mode"`
+
+	// TCP, TLS: port to dial on the target host.
+	Port int `json:"port,omitempty"`
+
+	// HTTP
+	URL                string `json:"url,omitempty"`
+	ExpectStatusMin    int    `json:"expect_status_min,omitempty"`
+	ExpectStatusMax    int    `json:"expect_status_max,omitempty"`
+	FollowRedirects    bool   `json:"follow_redirects,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+
+	// DNS: resolver address (host:port). Empty uses the system resolver.
+	Resolver string `json:"resolver,omitempty"`
+}
+
+// TargetConfig describes one independently-monitored target: where to
+// probe, how, and how much history to keep for it.
+type TargetConfig struct {
+	ID      string      `json:"id"`
+	Label   string      `json:"label"`
+	Address string      `json:"address"`
+	Probe   ProbeConfig `json:"probe"`
+
+	Interval time.Duration `json:"interval"`
+	Timeout  time.Duration `json:"timeout"`
+	History  int           `json:"history"`
+}
+
+// normalize fills in defaults for any zero-valued fields.
+func (t *TargetConfig) normalize() {
+	if t.Address == "" {
+		t.Address = "1.1.1.1"
+	}
+	if t.ID == "" {
+		t.ID = t.Address
+	}
+	if t.Label == "" {
+		t.Label = t.Address
+	}
+	if !t.Probe.Mode.Valid() {
+		t.Probe = DefaultProbeConfig(ProbeModeICMP)
+	}
+	if t.Interval <= 0 {
+		t.Interval = time.Second
+	}
+	if t.Timeout <= 0 {
+		t.Timeout = 2 * time.Second
+	}
+	if t.History <= 0 {
+		t.History = 60
+	}
+}
+
+// ExporterKind selects which metrics backend an ExporterConfig describes.
+type ExporterKind string
+
+const (
+	ExporterKindPrometheus ExporterKind = "prometheus"
+	ExporterKindStatsD     ExporterKind = "statsd"
+)
+
+// ExporterConfig describes one metrics sink. Only the fields relevant to
+// Kind are populated; the rest are left zero-valued and ignored.
+type ExporterConfig struct {
+	Kind ExporterKind `json:"kind"`
+
+	// Prometheus: address the /metrics HTTP server listens on, e.g. ":9090".
+	ListenAddr string `json:"listen_addr,omitempty"`
+
+	// StatsD: UDP host:port of the StatsD daemon, and an optional prefix
+	// prepended to every metric name.
+	Addr   string `json:"addr,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// AlertRuleKind selects the condition an AlertRule watches for.
+type AlertRuleKind string
+
+const (
+	AlertRuleLatencyAbove AlertRuleKind = "latency_above"
+	AlertRuleLossAbove    AlertRuleKind = "loss_above"
+	AlertRuleDown         AlertRuleKind = "down"
+)
+
+// AlertRule describes one condition to watch across a target's samples,
+// with separate trigger/clear thresholds (hysteresis) and a cooldown so a
+// metric oscillating around its threshold doesn't fire repeatedly. Only
+// the fields relevant to Kind are populated.
+type AlertRule struct {
+	ID     string        `json:"id"`
+	Target string        `json:"target,omitempty"` // target ID this rule watches, or "" for every target
+	Kind   AlertRuleKind `json:"kind"`
+
+	// latency_above: the rule fires once Samples consecutive successful
+	// probes all reach or exceed TriggerLatencyUS, and clears once Samples
+	// consecutive successful probes all fall back to ClearLatencyUS or
+	// below.
+	TriggerLatencyUS int `json:"trigger_latency_us,omitempty"`
+	ClearLatencyUS   int `json:"clear_latency_us,omitempty"`
+	Samples          int `json:"samples,omitempty"`
+
+	// loss_above: the rule fires once the failure rate over the trailing
+	// Samples probes reaches TriggerLossPct, and clears once it falls back
+	// to ClearLossPct or below.
+	TriggerLossPct float64 `json:"trigger_loss_pct,omitempty"`
+	ClearLossPct   float64 `json:"clear_loss_pct,omitempty"`
+
+	// down: the rule fires after ConsecutiveFailures probes in a row fail,
+	// and clears on the next success.
+	ConsecutiveFailures int `json:"consecutive_failures,omitempty"`
+
+	// Cooldown is the minimum time between this rule re-firing after it
+	// last fired, to avoid flapping.
+	Cooldown time.Duration `json:"cooldown,omitempty"`
+}
+
+// AlertSinkKind selects which notification channel an AlertSinkConfig
+// describes.
+type AlertSinkKind string
+
+const (
+	AlertSinkNotification AlertSinkKind = "notification"
+	AlertSinkWebhook      AlertSinkKind = "webhook"
+	AlertSinkLog          AlertSinkKind = "log"
+)
+
+// AlertSinkConfig describes one alert delivery channel. Only the fields
+// relevant to Kind are populated.
+type AlertSinkConfig struct {
+	Kind AlertSinkKind `json:"kind"`
+
+	// webhook: URL to POST the event JSON to, and an optional secret used
+	// to sign the body (HMAC-SHA256, sent as the X-Pinger-Signature header).
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
 type Config struct {
-	Target    string    `json:"target"`
-	ProbeMode ProbeMode `json:"probe_mode"`
+	Targets   []TargetConfig   `json:"targets"`
+	Exporters []ExporterConfig `json:"exporters,omitempty"`
+
+	Alerts     []AlertRule       `json:"alerts,omitempty"`
+	AlertSinks []AlertSinkConfig `json:"alert_sinks,omitempty"`
 }
 
-// Default returns the default configuration.
+// Default returns the default configuration: a single target pinging
+// 1.1.1.1 over ICMP.
 func Default() *Config {
 	return &Config{
-		Target:    "1.1.1.1",
-		ProbeMode: ProbeModeICMP,
+		Targets: []TargetConfig{
+			{
+				ID:       "default",
+				Label:    "1.1.1.1",
+				Address:  "1.1.1.1",
+				Probe:    DefaultProbeConfig(ProbeModeICMP),
+				Interval: time.Second,
+				Timeout:  2 * time.Second,
+				History:  60,
+			},
+		},
+	}
+}
+
+// DefaultProbeConfig returns the default configuration for mode.
+func DefaultProbeConfig(mode ProbeMode) ProbeConfig {
+	switch mode {
+	case ProbeModeTCP:
+		return ProbeConfig{Mode: mode, Port: 80}
+	case ProbeModeTLS:
+		return ProbeConfig{Mode: mode, Port: 443}
+	case ProbeModeHTTP:
+		return ProbeConfig{Mode: mode, ExpectStatusMin: 200, ExpectStatusMax: 399}
+	default:
+		return ProbeConfig{Mode: mode}
+	}
+}
+
+// AddTarget appends a new target monitoring address, assigning it a fresh
+// ID, and returns the resulting TargetConfig.
+func (c *Config) AddTarget(address string) TargetConfig {
+	t := TargetConfig{ID: newTargetID(), Address: address, Label: address}
+	t.normalize()
+	c.Targets = append(c.Targets, t)
+	return t
+}
+
+// RemoveTarget deletes the target with the given ID, reporting whether one
+// was found.
+func (c *Config) RemoveTarget(id string) bool {
+	for i, t := range c.Targets {
+		if t.ID == id {
+			c.Targets = append(c.Targets[:i], c.Targets[i+1:]...)
+			return true
+		}
 	}
+	return false
+}
+
+func newTargetID() string {
+	return fmt.Sprintf("target-%d", time.Now().UnixNano())
 }
 
 // configPath returns the path to the config file.
@@ -59,12 +271,11 @@ func Load() (*Config, error) {
 		return Default(), nil
 	}
 
-	// Validate and set defaults for missing fields
-	if cfg.Target == "" {
-		cfg.Target = "1.1.1.1"
+	if len(cfg.Targets) == 0 {
+		return Default(), nil
 	}
-	if cfg.ProbeMode != ProbeModeICMP && cfg.ProbeMode != ProbeModeHTTP {
-		cfg.ProbeMode = ProbeModeICMP
+	for i := range cfg.Targets {
+		cfg.Targets[i].normalize()
 	}
 
 	return &cfg, nil
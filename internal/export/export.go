@@ -0,0 +1,16 @@
+// Package export publishes probe samples to external metrics backends
+// (Prometheus, StatsD) so pinger's results can feed existing monitoring
+// pipelines alongside its own menubar UI.
+package export
+
+import "github.com/nenad/pinger/internal/ping"
+
+// Exporter publishes ping samples to an external system. Implementations
+// must be safe to call from the per-exporter worker goroutine Fanout
+// starts, separate from the goroutine running Fanout.Run, and must not
+// block for long, since a slow Publish delays every other sample queued
+// behind it for that exporter.
+type Exporter interface {
+	Publish(sample ping.TargetSample)
+	Close() error
+}
@@ -0,0 +1,51 @@
+package export
+
+import "github.com/nenad/pinger/internal/ping"
+
+// Fanout delivers every sample from a TargetSet's result channel to each
+// configured Exporter, through a small per-exporter ring buffer so a slow
+// exporter can't back-pressure the ping loop or starve the others —
+// mirrors the "drop if full" policy Manager already applies to its own
+// result channel.
+type Fanout struct {
+	exporters []Exporter
+	queues    []chan ping.TargetSample
+}
+
+// NewFanout starts one worker goroutine per exporter, each draining its
+// own queue and calling Publish.
+func NewFanout(exporters []Exporter) *Fanout {
+	f := &Fanout{exporters: exporters}
+	for _, e := range exporters {
+		q := make(chan ping.TargetSample, 32)
+		f.queues = append(f.queues, q)
+		go func(e Exporter, q chan ping.TargetSample) {
+			for sample := range q {
+				e.Publish(sample)
+			}
+		}(e, q)
+	}
+	return f
+}
+
+// Run drains results, queuing each sample for every exporter, until
+// results is closed. It blocks, so callers should run it in its own
+// goroutine.
+func (f *Fanout) Run(results <-chan ping.TargetSample) {
+	for sample := range results {
+		for _, q := range f.queues {
+			select {
+			case q <- sample:
+			default:
+				// drop if full
+			}
+		}
+	}
+}
+
+// Close closes every exporter.
+func (f *Fanout) Close() {
+	for _, e := range f.exporters {
+		_ = e.Close()
+	}
+}
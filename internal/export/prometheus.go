@@ -0,0 +1,80 @@
+package export
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/nenad/pinger/internal/ping"
+)
+
+// PrometheusExporter serves pinger_latency_seconds, pinger_up, and
+// pinger_failures_total on a local HTTP server for Prometheus to scrape.
+type PrometheusExporter struct {
+	server *http.Server
+
+	latency  *prometheus.HistogramVec
+	up       *prometheus.GaugeVec
+	failures *prometheus.CounterVec
+}
+
+// NewPrometheusExporter starts an HTTP server on addr (e.g. ":9090")
+// exposing /metrics.
+func NewPrometheusExporter(addr string) (*PrometheusExporter, error) {
+	if addr == "" {
+		addr = ":9090"
+	}
+
+	reg := prometheus.NewRegistry()
+	e := &PrometheusExporter{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pinger_latency_seconds",
+			Help:    "Probe latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"target", "probe"}),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "pinger_up",
+			Help: "1 if the most recent probe for a target succeeded, 0 otherwise.",
+		}, []string{"target", "probe"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pinger_failures_total",
+			Help: "Total number of failed probes.",
+		}, []string{"target", "probe"}),
+	}
+	reg.MustRegister(e.latency, e.up, e.failures)
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	e.server = &http.Server{Handler: mux}
+	go e.server.Serve(ln)
+
+	return e, nil
+}
+
+// Publish implements Exporter.
+func (e *PrometheusExporter) Publish(sample ping.TargetSample) {
+	labels := prometheus.Labels{"target": sample.TargetID, "probe": string(sample.Sample.ProbeMode)}
+	if sample.Sample.Failed {
+		e.up.With(labels).Set(0)
+		e.failures.With(labels).Inc()
+		return
+	}
+	e.up.With(labels).Set(1)
+	e.latency.With(labels).Observe(sample.Sample.Latency.Seconds())
+}
+
+// Close shuts down the /metrics HTTP server.
+func (e *PrometheusExporter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return e.server.Shutdown(ctx)
+}
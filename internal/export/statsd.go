@@ -0,0 +1,65 @@
+package export
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nenad/pinger/internal/ping"
+)
+
+// StatsDExporter sends pinger.<target>.<probe>.latency timings and
+// pinger.<target>.<probe>.failure counters to a StatsD daemon over UDP,
+// using the plain StatsD wire format (no DogStatsD-style "#tag:value"
+// suffix, which a vanilla StatsD daemon would treat as part of a
+// malformed metric name).
+type StatsDExporter struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsDExporter dials a UDP connection to addr (host:port). prefix, if
+// non-empty, is prepended to every metric name with a trailing dot.
+func NewStatsDExporter(addr, prefix string) (*StatsDExporter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDExporter{conn: conn, prefix: prefix}, nil
+}
+
+func (e *StatsDExporter) metric(sample ping.TargetSample, name string) string {
+	parts := []string{"pinger", sanitizeMetricPart(sample.TargetID), sanitizeMetricPart(string(sample.Sample.ProbeMode)), name}
+	if e.prefix != "" {
+		parts = append([]string{e.prefix}, parts...)
+	}
+	return strings.Join(parts, ".")
+}
+
+// sanitizeMetricPart replaces characters StatsD's dot-delimited,
+// colon-terminated wire format can't carry in a name segment (e.g. a
+// target ID that's a URL, with ':' and '/') with '_'.
+func sanitizeMetricPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// Publish implements Exporter.
+func (e *StatsDExporter) Publish(sample ping.TargetSample) {
+	if sample.Sample.Failed {
+		fmt.Fprintf(e.conn, "%s:1|c\n", e.metric(sample, "failure"))
+		return
+	}
+	fmt.Fprintf(e.conn, "%s:%d|ms\n", e.metric(sample, "latency"), sample.Sample.Latency.Milliseconds())
+}
+
+// Close closes the UDP connection.
+func (e *StatsDExporter) Close() error {
+	return e.conn.Close()
+}
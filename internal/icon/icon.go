@@ -19,23 +19,100 @@ const (
 	padBottom = 2 // Bottom padding to ensure low latency dots are visible
 )
 
-// Render generates a PNG icon representing the latency history as a sparkline.
-// Creates a monochrome template icon (black on transparent) for proper menubar display.
-func Render(history *pinger.History, inFlightAge int64) []byte {
+// RenderTargets composes an aggregate menubar icon across multiple
+// targets. Up to 4 targets are laid out as mini-sparklines side-by-side
+// within the 24x24 canvas; beyond that, individual sparklines would be
+// illegible at this scale, so it falls back to a compact grid of status
+// dots (one per target, reflecting that target's latest sample). firing
+// draws a small alert badge in the top-right corner so a firing rule is
+// visible at a glance; inFlight draws a faint dot in the bottom-left
+// corner while any target has a probe outstanding.
+func RenderTargets(histories []*pinger.History, firing, inFlight bool) []byte {
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
 
+	n := len(histories)
+	switch {
+	case n == 0:
+		drawDot(img, image.Pt(width/2, height/2), color.Black)
+	case n <= 4:
+		cellW := width / n
+		for i, h := range histories {
+			x0 := i * cellW
+			x1 := x0 + cellW
+			if i == n-1 {
+				x1 = width // absorb the rounding remainder into the last cell
+			}
+			drawSparkline(img, image.Rect(x0, 0, x1, height), h)
+		}
+	default:
+		drawStatusGrid(img, histories)
+	}
+
+	if firing {
+		drawAlertBadge(img)
+	}
+	if inFlight {
+		drawInFlightBadge(img)
+	}
+	return encode(img)
+}
+
+// drawAlertBadge marks the top-right corner of the icon so menubar users
+// can see a rule is firing without opening the menu.
+func drawAlertBadge(img *image.RGBA) {
+	r := image.Rect(width-5, 0, width, 5)
+	draw.Draw(img, r, &image.Uniform{color.Black}, image.Point{}, draw.Over)
+}
+
+// drawInFlightBadge marks the bottom-left corner with a faint dot
+// (template icons render color as opacity) while a probe is outstanding,
+// since in-flight probes don't themselves produce a new sparkline point.
+func drawInFlightBadge(img *image.RGBA) {
+	r := image.Rect(0, height-5, 5, height)
+	draw.Draw(img, r, &image.Uniform{color.RGBA{A: 90}}, image.Point{}, draw.Over)
+}
+
+// drawStatusGrid renders one colored dot per target, laid out in a grid
+// that fills the canvas as evenly as possible.
+func drawStatusGrid(img *image.RGBA, histories []*pinger.History) {
+	n := len(histories)
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	rows := int(math.Ceil(float64(n) / float64(cols)))
+	cellW := float64(width) / float64(cols)
+	cellH := float64(height) / float64(rows)
+
+	for i, h := range histories {
+		col := i % cols
+		row := i / cols
+		cx := int(cellW*(float64(col)+0.5) + 0.5)
+		cy := int(cellH*(float64(row)+0.5) + 0.5)
+
+		c := color.Color(color.Black)
+		if latest := h.Latest(1); len(latest) > 0 && latest[0].Failed {
+			// A faint dot (template icons render color as opacity) marks a
+			// target that's currently down.
+			c = color.RGBA{A: 90}
+		}
+		drawDot(img, image.Pt(cx, cy), c)
+	}
+}
+
+// drawSparkline plots history as a sparkline within bounds, using a
+// three-band latency scaling (0–100ms, 100–200ms, 200ms and above).
+func drawSparkline(img *image.RGBA, bounds image.Rectangle, history *pinger.History) {
+	w := bounds.Dx()
 	series := history.Snapshot()
 	n := len(series)
 	if n == 0 {
 		// Draw a simple indicator dot when no history exists
-		drawDot(img, image.Pt(width/2, height/2))
-		return encode(img)
+		drawDot(img, image.Pt(bounds.Min.X+w/2, height/2), color.Black)
+		return
 	}
 
 	// Compute x positions and available plot height
-	plotW := float64(width - 2*padX)
+	plotW := float64(w - 2*padX)
 	if plotW <= 0 {
-		plotW = float64(width)
+		plotW = float64(w)
 	}
 	plotH := float64(height - padY - padBottom)
 
@@ -81,7 +158,7 @@ func Render(history *pinger.History, inFlightAge int64) []byte {
 				y = 2.0*bandHeight + bandHeight*ratio
 			}
 		}
-		x := float64(padX) + float64(i)*stepX
+		x := float64(bounds.Min.X+padX) + float64(i)*stepX
 		yy := float64(padY) + (plotH - y)
 		points[i] = image.Pt(int(x+0.5), int(yy+0.5))
 	}
@@ -95,11 +172,9 @@ func Render(history *pinger.History, inFlightAge int64) []byte {
 	for i := 0; i < n; i++ {
 		if series[i].Failed {
 			p := points[i]
-			drawDot(img, image.Pt(p.X, padY+2))
+			drawDot(img, image.Pt(p.X, padY+2), color.Black)
 		}
 	}
-
-	return encode(img)
 }
 
 func encode(img image.Image) []byte {
@@ -108,9 +183,9 @@ func encode(img image.Image) []byte {
 	return buf.Bytes()
 }
 
-func drawDot(img *image.RGBA, p image.Point) {
+func drawDot(img *image.RGBA, p image.Point, c color.Color) {
 	r := image.Rect(p.X-1, p.Y-1, p.X+2, p.Y+2)
-	draw.Draw(img, r, &image.Uniform{color.Black}, image.Point{}, draw.Over)
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Over)
 }
 
 // Bresenham-like line drawing
@@ -3,20 +3,43 @@ package ping
 import (
 	"context"
 	"errors"
-	"net"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/nenad/pinger/internal/config"
-	probing "github.com/prometheus-community/pro-bing"
 )
 
 // Sample represents a single ping result.
 type Sample struct {
-	Timestamp   time.Time
-	Latency     time.Duration
-	Failed      bool
+	Timestamp time.Time
+	Latency   time.Duration
+	Failed    bool
+	// TTFB is the time to first response byte, populated by probes that
+	// can distinguish it from total latency (e.g. http). Zero otherwise.
+	TTFB        time.Duration
 	Description string
+	// ProbeMode is the mode that produced this sample, stamped by Manager
+	// so consumers fanned out across multiple targets (e.g. exporters) can
+	// label it without holding a reference back to the Manager.
+	ProbeMode config.ProbeMode
+}
+
+// Stats aggregates a target's count, loss, and latency distribution over
+// some window.
+type Stats struct {
+	Count       int64
+	Failures    int64
+	MeanLatency time.Duration
+	P50         time.Duration
+	P95         time.Duration
+	P99         time.Duration
+}
+
+// LongWindowSource supplies Stats for windows longer than a History's
+// in-memory ring can cover, backed by a persistent store.
+type LongWindowSource interface {
+	Stats(target string, window time.Duration) (Stats, error)
 }
 
 // History is a ring buffer of recent Samples.
@@ -26,6 +49,9 @@ type History struct {
 	capacity int
 	nextIdx  int
 	size     int
+
+	longWindowTarget string
+	longWindow       LongWindowSource
 }
 
 func NewHistory(capacity int) *History {
@@ -38,6 +64,16 @@ func NewHistory(capacity int) *History {
 	}
 }
 
+// SetLongWindowSource configures src to serve Stats queries for windows
+// that exceed what the in-memory ring retains, e.g. a persistent
+// internal/store.DB. target identifies this History to src.
+func (h *History) SetLongWindowSource(target string, src LongWindowSource) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.longWindowTarget = target
+	h.longWindow = src
+}
+
 func (h *History) Add(sample Sample) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -48,6 +84,60 @@ func (h *History) Add(sample Sample) {
 	}
 }
 
+// Stats returns aggregate stats over the trailing window. Windows that
+// fit within the in-memory ring are computed directly from it; longer
+// windows fall back to the configured LongWindowSource, if any.
+func (h *History) Stats(window time.Duration) Stats {
+	h.mu.RLock()
+	series := h.snapshotLocked()
+	longWindow := h.longWindow
+	target := h.longWindowTarget
+	h.mu.RUnlock()
+
+	if len(series) > 0 && longWindow != nil && window > time.Since(series[0].Timestamp) {
+		if stats, err := longWindow.Stats(target, window); err == nil {
+			return stats
+		}
+	}
+	return statsFromSeries(series, window)
+}
+
+func statsFromSeries(series []Sample, window time.Duration) Stats {
+	cutoff := time.Now().Add(-window)
+	var stats Stats
+	var sum time.Duration
+	latenciesUS := make([]float64, 0, len(series))
+
+	for _, s := range series {
+		if s.Timestamp.Before(cutoff) {
+			continue
+		}
+		stats.Count++
+		if s.Failed {
+			stats.Failures++
+			continue
+		}
+		sum += s.Latency
+		latenciesUS = append(latenciesUS, float64(s.Latency.Microseconds()))
+	}
+
+	if len(latenciesUS) > 0 {
+		stats.MeanLatency = sum / time.Duration(len(latenciesUS))
+		sort.Float64s(latenciesUS)
+		stats.P50 = time.Duration(exactPercentile(latenciesUS, 0.50)) * time.Microsecond
+		stats.P95 = time.Duration(exactPercentile(latenciesUS, 0.95)) * time.Microsecond
+		stats.P99 = time.Duration(exactPercentile(latenciesUS, 0.99)) * time.Microsecond
+	}
+	return stats
+}
+
+// exactPercentile returns the p-th percentile of sorted, a slice already
+// sorted ascending.
+func exactPercentile(sorted []float64, p float64) float64 {
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
 // Latest returns up to n latest samples, most recent first.
 func (h *History) Latest(n int) []Sample {
 	h.mu.RLock()
@@ -69,6 +159,12 @@ func (h *History) Latest(n int) []Sample {
 func (h *History) Snapshot() []Sample {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	return h.snapshotLocked()
+}
+
+// snapshotLocked is Snapshot without acquiring the lock; callers must hold
+// h.mu (for reading or writing).
+func (h *History) snapshotLocked() []Sample {
 	out := make([]Sample, 0, h.size)
 	start := (h.nextIdx - h.size + h.capacity) % h.capacity
 	for i := 0; i < h.size; i++ {
@@ -82,16 +178,19 @@ type Manager struct {
 	target        string
 	interval      time.Duration
 	timeout       time.Duration
-	probeMode     config.ProbeMode
+	probeCfg      config.ProbeConfig
+	probe         Probe
 	history       *History
 	mu            sync.RWMutex
 	inFlightStart time.Time
 	inFlight      bool
 	cancel        context.CancelFunc
 	resultCh      chan Sample
+	closed        chan struct{}
+	closeOnce     sync.Once
 }
 
-func NewManager(target string, interval time.Duration, timeout time.Duration, probeMode config.ProbeMode, historyCapacity int) *Manager {
+func NewManager(target string, interval time.Duration, timeout time.Duration, probeCfg config.ProbeConfig, historyCapacity int) *Manager {
 	if target == "" {
 		target = "1.1.1.1"
 	}
@@ -101,21 +200,30 @@ func NewManager(target string, interval time.Duration, timeout time.Duration, pr
 	if timeout <= 0 {
 		timeout = 2 * time.Second
 	}
-	if probeMode != config.ProbeModeICMP && probeMode != config.ProbeModeHTTP {
-		probeMode = config.ProbeModeICMP
+	if !probeCfg.Mode.Valid() {
+		probeCfg = config.ProbeConfig{Mode: config.ProbeModeICMP}
 	}
 	return &Manager{
-		target:    target,
-		interval:  interval,
-		timeout:   timeout,
-		probeMode: probeMode,
-		history:   NewHistory(historyCapacity),
-		resultCh:  make(chan Sample, 100),
+		target:   target,
+		interval: interval,
+		timeout:  timeout,
+		probeCfg: probeCfg,
+		history:  NewHistory(historyCapacity),
+		resultCh: make(chan Sample, 100),
+		closed:   make(chan struct{}),
 	}
 }
 
 func (m *Manager) Results() <-chan Sample { return m.resultCh }
 
+// Done returns a channel that's closed once Close has been called,
+// distinct from the per-loop context Stop cancels, since Restart cancels
+// and recreates that context while keeping the Manager (and resultCh)
+// alive. Consumers that want to know the Manager is gone for good, e.g.
+// TargetSet's fan-in goroutine, should select on this instead of ranging
+// over Results().
+func (m *Manager) Done() <-chan struct{} { return m.closed }
+
 func (m *Manager) History() *History { return m.history }
 
 func (m *Manager) Target() string {
@@ -127,7 +235,7 @@ func (m *Manager) Target() string {
 func (m *Manager) ProbeMode() config.ProbeMode {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.probeMode
+	return m.probeCfg.Mode
 }
 
 // SetTarget changes the target address. Requires restart to take effect.
@@ -137,10 +245,18 @@ func (m *Manager) SetTarget(target string) {
 	m.mu.Unlock()
 }
 
-// SetProbeMode changes the probe mode. Requires restart to take effect.
+// SetProbeMode switches to mode using its default configuration. Requires
+// restart to take effect. Use SetProbeConfig to customize mode-specific
+// fields (e.g. HTTP URL, TCP port).
 func (m *Manager) SetProbeMode(mode config.ProbeMode) {
+	m.SetProbeConfig(config.ProbeConfig{Mode: mode})
+}
+
+// SetProbeConfig changes the active probe and its configuration. Requires
+// restart to take effect.
+func (m *Manager) SetProbeConfig(cfg config.ProbeConfig) {
 	m.mu.Lock()
-	m.probeMode = mode
+	m.probeCfg = cfg
 	m.mu.Unlock()
 }
 
@@ -172,17 +288,39 @@ func (m *Manager) markInFlight(start bool) {
 }
 
 func (m *Manager) Start() {
+	m.mu.Lock()
+	m.probe = m.buildProbe()
+	m.mu.Unlock()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	m.cancel = cancel
 	go m.loop(ctx)
 }
 
+// buildProbe constructs the Probe for the current probeCfg, falling back
+// to icmp if the configured mode isn't registered. Callers must hold m.mu.
+func (m *Manager) buildProbe() Probe {
+	probe, err := newProbe(m.probeCfg)
+	if err != nil {
+		probe, _ = newProbe(config.ProbeConfig{Mode: config.ProbeModeICMP})
+	}
+	return probe
+}
+
 func (m *Manager) Stop() {
 	if m.cancel != nil {
 		m.cancel()
 	}
 }
 
+// Close stops the loop for good and signals Done, for callers (like
+// TargetSet.Remove) that are discarding the Manager rather than
+// restarting it.
+func (m *Manager) Close() {
+	m.Stop()
+	m.closeOnce.Do(func() { close(m.closed) })
+}
+
 func (m *Manager) loop(ctx context.Context) {
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
@@ -202,22 +340,22 @@ func (m *Manager) doPing(ctx context.Context) {
 	m.markInFlight(true)
 	defer m.markInFlight(false)
 
-	mode := m.ProbeMode()
-	target := m.Target()
-
-	var sample Sample
-	var err error
+	m.mu.RLock()
+	probe := m.probe
+	timeout := m.timeout
+	target := m.target
+	mode := m.probeCfg.Mode
+	m.mu.RUnlock()
 
-	if mode == config.ProbeModeHTTP {
-		sample, err = m.doHTTPProbe(ctx, target)
-	} else {
-		sample, err = m.doICMPPing(ctx, target)
-	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
+	sample, err := probe.Probe(probeCtx, target)
 	if err != nil {
-		m.emitFailure(err)
+		m.emitFailure(err, mode)
 		return
 	}
+	sample.ProbeMode = mode
 
 	m.history.Add(sample)
 	select {
@@ -227,63 +365,7 @@ func (m *Manager) doPing(ctx context.Context) {
 	}
 }
 
-func (m *Manager) doICMPPing(ctx context.Context, target string) (Sample, error) {
-	pinger, err := probing.NewPinger(target)
-	if err != nil {
-		return Sample{}, err
-	}
-	// Use unprivileged mode to avoid requiring root.
-	pinger.SetPrivileged(false)
-	pinger.Count = 1
-	pinger.Timeout = m.timeout
-
-	start := time.Now()
-	err = pinger.Run() // Blocks until finished
-	rtt := time.Since(start)
-	if err != nil {
-		return Sample{}, err
-	}
-	stats := pinger.Statistics()
-	var latency time.Duration
-	if stats != nil && stats.AvgRtt > 0 {
-		latency = stats.AvgRtt
-	} else {
-		// Fallback to measured elapsed
-		latency = rtt
-	}
-	return Sample{
-		Timestamp:   time.Now(),
-		Latency:     latency,
-		Failed:      false,
-		Description: "ok",
-	}, nil
-}
-
-func (m *Manager) doHTTPProbe(ctx context.Context, target string) (Sample, error) {
-	// Attempt TCP connection to port 80
-	address := net.JoinHostPort(target, "80")
-	start := time.Now()
-
-	dialer := net.Dialer{
-		Timeout: m.timeout,
-	}
-	conn, err := dialer.DialContext(ctx, "tcp", address)
-	latency := time.Since(start)
-
-	if err != nil {
-		return Sample{}, err
-	}
-	conn.Close()
-
-	return Sample{
-		Timestamp:   time.Now(),
-		Latency:     latency,
-		Failed:      false,
-		Description: "ok",
-	}, nil
-}
-
-func (m *Manager) emitFailure(err error) {
+func (m *Manager) emitFailure(err error, mode config.ProbeMode) {
 	if err == nil {
 		err = errors.New("ping failed")
 	}
@@ -292,6 +374,7 @@ func (m *Manager) emitFailure(err error) {
 		Latency:     0,
 		Failed:      true,
 		Description: err.Error(),
+		ProbeMode:   mode,
 	}
 	m.history.Add(sample)
 	select {
@@ -0,0 +1,62 @@
+package ping
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+// Probe performs a single check against a target and returns a Sample.
+// Implementations are registered under a name via Register and looked up
+// by config.ProbeMode when a Manager starts or restarts.
+type Probe interface {
+	Name() string
+	Probe(ctx context.Context, target string) (Sample, error)
+}
+
+// ProbeFactory builds a Probe from its configuration.
+type ProbeFactory func(cfg config.ProbeConfig) (Probe, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]ProbeFactory{}
+)
+
+// Register adds a probe factory under name, making it selectable via
+// config.ProbeMode(name). The built-in icmp/tcp/http/tls/dns probes
+// register themselves from init() in this package. A probe registered
+// under any other name is listed by RegisteredModes, but NewManager and
+// config loading accept only the modes config.ProbeMode.Valid knows and
+// replace any other mode with ICMP.
+func Register(name string, factory ProbeFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// newProbe looks up and constructs the probe registered for cfg.Mode.
+func newProbe(cfg config.ProbeConfig) (Probe, error) {
+	registryMu.RLock()
+	factory, ok := registry[string(cfg.Mode)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("ping: no probe registered for mode %q", cfg.Mode)
+	}
+	return factory(cfg)
+}
+
+// RegisteredModes returns the names of all registered probe kinds, sorted
+// alphabetically. ui.TrayApp uses this to populate the mode submenu.
+func RegisteredModes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	modes := make([]string, 0, len(registry))
+	for name := range registry {
+		modes = append(modes, name)
+	}
+	sort.Strings(modes)
+	return modes
+}
@@ -0,0 +1,56 @@
+package ping
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+func init() {
+	Register(string(config.ProbeModeDNS), newDNSProbe)
+}
+
+// dnsProbe resolves target against a specific resolver (or the system
+// resolver if none is configured) and records the query RTT.
+type dnsProbe struct {
+	resolver *net.Resolver
+}
+
+func newDNSProbe(cfg config.ProbeConfig) (Probe, error) {
+	resolver := net.DefaultResolver
+	if cfg.Resolver != "" {
+		addr := cfg.Resolver
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	return &dnsProbe{resolver: resolver}, nil
+}
+
+func (p *dnsProbe) Name() string { return string(config.ProbeModeDNS) }
+
+func (p *dnsProbe) Probe(ctx context.Context, target string) (Sample, error) {
+	start := time.Now()
+	addrs, err := p.resolver.LookupHost(ctx, target)
+	latency := time.Since(start)
+	if err != nil {
+		return Sample{}, err
+	}
+	if len(addrs) == 0 {
+		return Sample{}, fmt.Errorf("dns probe: no records for %q", target)
+	}
+
+	return Sample{
+		Timestamp:   time.Now(),
+		Latency:     latency,
+		Failed:      false,
+		Description: fmt.Sprintf("ok resolved=%s", addrs[0]),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package ping
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+func init() {
+	Register(string(config.ProbeModeHTTP), newHTTPProbe)
+}
+
+// httpProbe performs a real GET request and validates the response status
+// falls within [statusMin, statusMax].
+type httpProbe struct {
+	url       string
+	statusMin int
+	statusMax int
+	client    *http.Client
+}
+
+func newHTTPProbe(cfg config.ProbeConfig) (Probe, error) {
+	statusMin, statusMax := cfg.ExpectStatusMin, cfg.ExpectStatusMax
+	if statusMin == 0 && statusMax == 0 {
+		statusMin, statusMax = 200, 399
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify},
+		},
+	}
+	if !cfg.FollowRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return &httpProbe{
+		url:       cfg.URL,
+		statusMin: statusMin,
+		statusMax: statusMax,
+		client:    client,
+	}, nil
+}
+
+func (p *httpProbe) Name() string { return string(config.ProbeModeHTTP) }
+
+func (p *httpProbe) Probe(ctx context.Context, target string) (Sample, error) {
+	url := p.url
+	if url == "" {
+		url = "http://" + target + "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Sample{}, err
+	}
+
+	var ttfb time.Duration
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			ttfb = time.Since(start)
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := p.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		return Sample{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < p.statusMin || resp.StatusCode > p.statusMax {
+		return Sample{}, fmt.Errorf("http probe: unexpected status %d (want %d-%d)", resp.StatusCode, p.statusMin, p.statusMax)
+	}
+
+	return Sample{
+		Timestamp:   time.Now(),
+		Latency:     latency,
+		TTFB:        ttfb,
+		Failed:      false,
+		Description: fmt.Sprintf("ok status=%d", resp.StatusCode),
+	}, nil
+}
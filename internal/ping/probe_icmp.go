@@ -0,0 +1,53 @@
+package ping
+
+import (
+	"context"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+	probing "github.com/prometheus-community/pro-bing"
+)
+
+func init() {
+	Register(string(config.ProbeModeICMP), newICMPProbe)
+}
+
+// icmpProbe sends a single unprivileged ICMP echo request via pro-bing.
+type icmpProbe struct{}
+
+func newICMPProbe(cfg config.ProbeConfig) (Probe, error) {
+	return &icmpProbe{}, nil
+}
+
+func (p *icmpProbe) Name() string { return string(config.ProbeModeICMP) }
+
+func (p *icmpProbe) Probe(ctx context.Context, target string) (Sample, error) {
+	pinger, err := probing.NewPinger(target)
+	if err != nil {
+		return Sample{}, err
+	}
+	// Use unprivileged mode to avoid requiring root.
+	pinger.SetPrivileged(false)
+	pinger.Count = 1
+	if deadline, ok := ctx.Deadline(); ok {
+		pinger.Timeout = time.Until(deadline)
+	}
+
+	start := time.Now()
+	if err := pinger.Run(); err != nil { // Blocks until finished
+		return Sample{}, err
+	}
+	rtt := time.Since(start)
+
+	stats := pinger.Statistics()
+	latency := rtt
+	if stats != nil && stats.AvgRtt > 0 {
+		latency = stats.AvgRtt
+	}
+	return Sample{
+		Timestamp:   time.Now(),
+		Latency:     latency,
+		Failed:      false,
+		Description: "ok",
+	}, nil
+}
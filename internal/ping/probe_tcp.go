@@ -0,0 +1,49 @@
+package ping
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+func init() {
+	Register(string(config.ProbeModeTCP), newTCPProbe)
+}
+
+// tcpProbe measures the time to establish a TCP connection to host:port.
+type tcpProbe struct {
+	port int
+}
+
+func newTCPProbe(cfg config.ProbeConfig) (Probe, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = 80
+	}
+	return &tcpProbe{port: port}, nil
+}
+
+func (p *tcpProbe) Name() string { return string(config.ProbeModeTCP) }
+
+func (p *tcpProbe) Probe(ctx context.Context, target string) (Sample, error) {
+	address := net.JoinHostPort(target, strconv.Itoa(p.port))
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	latency := time.Since(start)
+	if err != nil {
+		return Sample{}, err
+	}
+	conn.Close()
+
+	return Sample{
+		Timestamp:   time.Now(),
+		Latency:     latency,
+		Failed:      false,
+		Description: "ok",
+	}, nil
+}
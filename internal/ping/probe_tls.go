@@ -0,0 +1,61 @@
+package ping
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+func init() {
+	Register(string(config.ProbeModeTLS), newTLSProbe)
+}
+
+// tlsProbe measures the full TLS handshake time to host:port and reports
+// the leaf certificate's expiry in Sample.Description.
+type tlsProbe struct {
+	port int
+}
+
+func newTLSProbe(cfg config.ProbeConfig) (Probe, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = 443
+	}
+	return &tlsProbe{port: port}, nil
+}
+
+func (p *tlsProbe) Name() string { return string(config.ProbeModeTLS) }
+
+func (p *tlsProbe) Probe(ctx context.Context, target string) (Sample, error) {
+	address := net.JoinHostPort(target, strconv.Itoa(p.port))
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{},
+		Config:    &tls.Config{ServerName: target},
+	}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	latency := time.Since(start)
+	if err != nil {
+		return Sample{}, err
+	}
+	tlsConn := conn.(*tls.Conn)
+	defer tlsConn.Close()
+
+	desc := "ok"
+	if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+		desc = fmt.Sprintf("ok expires=%s", certs[0].NotAfter.Format(time.RFC3339))
+	}
+
+	return Sample{
+		Timestamp:   time.Now(),
+		Latency:     latency,
+		Failed:      false,
+		Description: desc,
+	}, nil
+}
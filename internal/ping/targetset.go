@@ -0,0 +1,137 @@
+package ping
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nenad/pinger/internal/config"
+)
+
+// TargetSample tags a Sample with the ID of the target that produced it.
+type TargetSample struct {
+	TargetID string
+	Sample   Sample
+}
+
+// TargetSet manages N independent Managers keyed by a stable target ID,
+// each with its own History, probe config, and result channel, and fans
+// their samples out to every subscriber, tagged with the target ID.
+type TargetSet struct {
+	mu          sync.RWMutex
+	managers    map[string]*Manager
+	order       []string // preserves the order targets were added in
+	subscribers []chan TargetSample
+}
+
+// NewTargetSet creates an empty TargetSet.
+func NewTargetSet() *TargetSet {
+	return &TargetSet{
+		managers: make(map[string]*Manager),
+	}
+}
+
+// Results returns a new channel that receives every sample from every
+// target. Each call creates an independent subscription, so the UI, the
+// persistent store, and any exporters can all observe every sample
+// without racing each other or stealing one another's deliveries.
+func (ts *TargetSet) Results() <-chan TargetSample {
+	ch := make(chan TargetSample, 100)
+	ts.mu.Lock()
+	ts.subscribers = append(ts.subscribers, ch)
+	ts.mu.Unlock()
+	return ch
+}
+
+func (ts *TargetSet) publish(sample TargetSample) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	for _, ch := range ts.subscribers {
+		select {
+		case ch <- sample:
+		default:
+			// drop if full
+		}
+	}
+}
+
+// Add creates, starts, and registers a Manager for target under id.
+func (ts *TargetSet) Add(id, target string, interval, timeout time.Duration, probeCfg config.ProbeConfig, historyCapacity int) *Manager {
+	mgr := NewManager(target, interval, timeout, probeCfg, historyCapacity)
+
+	ts.mu.Lock()
+	ts.managers[id] = mgr
+	ts.order = append(ts.order, id)
+	ts.mu.Unlock()
+
+	mgr.Start()
+	go ts.fanIn(id, mgr)
+	return mgr
+}
+
+// fanIn drains mgr's results until mgr is closed for good via Close.
+// It must not simply range over mgr.Results(): Restart cancels and
+// recreates mgr's loop without closing resultCh, so ranging over it
+// would never return and this goroutine would leak on every Remove.
+func (ts *TargetSet) fanIn(id string, mgr *Manager) {
+	results := mgr.Results()
+	done := mgr.Done()
+	for {
+		select {
+		case sample := <-results:
+			ts.publish(TargetSample{TargetID: id, Sample: sample})
+		case <-done:
+			return
+		}
+	}
+}
+
+// Remove stops and forgets the manager registered under id.
+func (ts *TargetSet) Remove(id string) {
+	ts.mu.Lock()
+	mgr, ok := ts.managers[id]
+	if ok {
+		delete(ts.managers, id)
+		for i, oid := range ts.order {
+			if oid == id {
+				ts.order = append(ts.order[:i], ts.order[i+1:]...)
+				break
+			}
+		}
+	}
+	ts.mu.Unlock()
+
+	if ok {
+		mgr.Close()
+	}
+}
+
+// Get returns the manager registered under id, if any.
+func (ts *TargetSet) Get(id string) (*Manager, bool) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	mgr, ok := ts.managers[id]
+	return mgr, ok
+}
+
+// IDs returns the registered target IDs in the order they were added.
+func (ts *TargetSet) IDs() []string {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	out := make([]string, len(ts.order))
+	copy(out, ts.order)
+	return out
+}
+
+// StopAll stops every managed target.
+func (ts *TargetSet) StopAll() {
+	ts.mu.RLock()
+	mgrs := make([]*Manager, 0, len(ts.managers))
+	for _, mgr := range ts.managers {
+		mgrs = append(mgrs, mgr)
+	}
+	ts.mu.RUnlock()
+
+	for _, mgr := range mgrs {
+		mgr.Stop()
+	}
+}
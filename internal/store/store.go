@@ -0,0 +1,159 @@
+// Package store persists ping samples to a local SQLite database and
+// serves the aggregate queries ping.History falls back to for windows
+// longer than its in-memory ring covers.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/nenad/pinger/internal/ping"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS samples (
+	target      TEXT NOT NULL,
+	ts          INTEGER NOT NULL,
+	latency_us  INTEGER NOT NULL,
+	failed      INTEGER NOT NULL,
+	description TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_samples_target_ts ON samples(target, ts);
+`
+
+// DB wraps a SQLite connection holding every sample ever recorded.
+type DB struct {
+	conn *sql.DB
+}
+
+// Open creates (if needed) and opens the SQLite database at the default
+// path, ~/.config/pinger/samples.db.
+func Open() (*DB, error) {
+	path, err := dbPath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenAt(path)
+}
+
+// OpenAt opens the SQLite database at path, creating its schema if absent.
+func OpenAt(path string) (*DB, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &DB{conn: conn}, nil
+}
+
+func dbPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(homeDir, ".config", "pinger")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "samples.db"), nil
+}
+
+// Record persists one sample for target.
+func (db *DB) Record(target string, sample ping.Sample) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO samples (target, ts, latency_us, failed, description) VALUES (?, ?, ?, ?, ?)`,
+		target, sample.Timestamp.UnixMicro(), sample.Latency.Microseconds(), boolToInt(sample.Failed), sample.Description,
+	)
+	return err
+}
+
+// Subscribe starts a goroutine that persists every sample delivered on
+// results until the channel is closed. Persist errors are logged, not
+// returned, since a write failure shouldn't interrupt monitoring.
+func (db *DB) Subscribe(results <-chan ping.TargetSample) {
+	go func() {
+		for ts := range results {
+			if err := db.Record(ts.TargetID, ts.Sample); err != nil {
+				fmt.Fprintf(os.Stderr, "store: failed to persist sample for %s: %v\n", ts.TargetID, err)
+			}
+		}
+	}()
+}
+
+// Stats implements ping.LongWindowSource, computing count/failure/mean/
+// percentile aggregates for target over window via SQL.
+func (db *DB) Stats(target string, window time.Duration) (ping.Stats, error) {
+	cutoff := time.Now().Add(-window).UnixMicro()
+
+	var stats ping.Stats
+	var meanUS float64
+	row := db.conn.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(failed), 0), COALESCE(AVG(CASE WHEN failed = 0 THEN latency_us END), 0)
+		 FROM samples WHERE target = ? AND ts >= ?`,
+		target, cutoff,
+	)
+	if err := row.Scan(&stats.Count, &stats.Failures, &meanUS); err != nil {
+		return ping.Stats{}, err
+	}
+	stats.MeanLatency = time.Duration(meanUS) * time.Microsecond
+
+	var err error
+	if stats.P50, err = db.percentile(target, cutoff, 0.50); err != nil {
+		return ping.Stats{}, err
+	}
+	if stats.P95, err = db.percentile(target, cutoff, 0.95); err != nil {
+		return ping.Stats{}, err
+	}
+	if stats.P99, err = db.percentile(target, cutoff, 0.99); err != nil {
+		return ping.Stats{}, err
+	}
+	return stats, nil
+}
+
+// percentile returns the p-th percentile latency for target's successful
+// samples since cutoff (a Unix microsecond timestamp), computed by
+// ordering and offsetting since SQLite has no built-in percentile
+// aggregate.
+func (db *DB) percentile(target string, cutoff int64, p float64) (time.Duration, error) {
+	var ok int64
+	if err := db.conn.QueryRow(
+		`SELECT COUNT(*) FROM samples WHERE target = ? AND ts >= ? AND failed = 0`,
+		target, cutoff,
+	).Scan(&ok); err != nil {
+		return 0, err
+	}
+	if ok == 0 {
+		return 0, nil
+	}
+
+	offset := int64(p * float64(ok-1))
+	var us int64
+	err := db.conn.QueryRow(
+		`SELECT latency_us FROM samples WHERE target = ? AND ts >= ? AND failed = 0 ORDER BY latency_us ASC LIMIT 1 OFFSET ?`,
+		target, cutoff, offset,
+	).Scan(&us)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(us) * time.Microsecond, nil
+}
+
+// Close closes the underlying database connection.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -10,26 +10,40 @@ import (
 
 	"github.com/getlantern/systray"
 
+	"github.com/nenad/pinger/internal/alert"
 	"github.com/nenad/pinger/internal/config"
 	renderer "github.com/nenad/pinger/internal/icon"
 	pinger "github.com/nenad/pinger/internal/ping"
 )
 
+// targetMenu holds the systray items backing one target's submenu.
+type targetMenu struct {
+	header      *systray.MenuItem
+	infoItem    *systray.MenuItem
+	statsItem   *systray.MenuItem
+	modeItems   map[config.ProbeMode]*systray.MenuItem
+	sampleItems []*systray.MenuItem
+}
+
 type TrayApp struct {
-	mgr              *pinger.Manager
-	cfg              *config.Config
-	targetLabel      *systray.MenuItem
-	menuItems        []*systray.MenuItem
-	changeTargetItem *systray.MenuItem
-	icmpModeItem     *systray.MenuItem
-	httpModeItem     *systray.MenuItem
+	targets *pinger.TargetSet
+	cfg     *config.Config
+	alerts  *alert.Engine
+
+	mu          sync.Mutex
+	targetMenus map[string]*targetMenu
+
+	addTargetItem    *systray.MenuItem
+	removeTargetItem *systray.MenuItem
+	alertsItem       *systray.MenuItem
 	quitItem         *systray.MenuItem
-	lastIcon         []byte
-	iconMu           sync.Mutex
+
+	lastIcon []byte
+	iconMu   sync.Mutex
 }
 
-func NewTrayApp(mgr *pinger.Manager, cfg *config.Config) *TrayApp {
-	return &TrayApp{mgr: mgr, cfg: cfg}
+func NewTrayApp(targets *pinger.TargetSet, cfg *config.Config, alerts *alert.Engine) *TrayApp {
+	return &TrayApp{targets: targets, cfg: cfg, alerts: alerts, targetMenus: make(map[string]*targetMenu)}
 }
 
 func (a *TrayApp) Run() {
@@ -37,98 +51,164 @@ func (a *TrayApp) Run() {
 }
 
 func (a *TrayApp) onReady() {
-	a.mgr.Start()
+	for _, t := range a.cfg.Targets {
+		a.addTargetMenu(t)
+	}
 	a.updateTooltip()
 
-	// Target label (read-only)
-	a.targetLabel = systray.AddMenuItem("", "Current target")
-	a.targetLabel.Disable()
-	a.updateTargetLabel()
 	systray.AddSeparator()
-
-	// Configuration menu
-	a.changeTargetItem = systray.AddMenuItem("Change Target...", "Change ping target")
-	systray.AddSeparator()
-
-	// Probe mode submenu
-	a.icmpModeItem = systray.AddMenuItem("ICMP Mode", "Use ICMP ping")
-	a.httpModeItem = systray.AddMenuItem("HTTP Mode", "Use HTTP probe (port 80)")
-	a.updateModeCheckmarks()
-
-	systray.AddSeparator()
-
-	// Initialize menu items for latest 20 pings
-	a.menuItems = make([]*systray.MenuItem, 20)
-	for i := 0; i < 20; i++ {
-		item := systray.AddMenuItem("…", "Ping sample")
-		a.menuItems[i] = item
-		go func(mi *systray.MenuItem) {
-			for range mi.ClickedCh {
-				// Selecting a menu item closes the popup on macOS; do nothing.
-			}
-		}(item)
-	}
+	a.addTargetItem = systray.AddMenuItem("Add Target...", "Monitor a new target")
+	a.removeTargetItem = systray.AddMenuItem("Remove Target...", "Stop monitoring a target")
+	a.alertsItem = systray.AddMenuItem("Alerts...", "Add an alert rule")
 
 	systray.AddSeparator()
 	a.quitItem = systray.AddMenuItem("Quit", "Quit Pinger")
 
-	// Handle change target
 	go func() {
-		for range a.changeTargetItem.ClickedCh {
-			a.handleChangeTarget()
+		for range a.addTargetItem.ClickedCh {
+			a.handleAddTarget()
 		}
 	}()
 
-	// Handle ICMP mode
 	go func() {
-		for range a.icmpModeItem.ClickedCh {
-			a.handleChangeMode(config.ProbeModeICMP)
+		for range a.removeTargetItem.ClickedCh {
+			a.handleRemoveTarget()
 		}
 	}()
 
-	// Handle HTTP mode
 	go func() {
-		for range a.httpModeItem.ClickedCh {
-			a.handleChangeMode(config.ProbeModeHTTP)
+		for range a.alertsItem.ClickedCh {
+			a.handleEditAlerts()
 		}
 	}()
 
-	// Handle quit
 	go func() {
 		<-a.quitItem.ClickedCh
 		systray.Quit()
 	}()
 
-	// Initial icon
-	a.updateIcon(0)
-	a.updateMenu()
+	a.updateIcon()
 
-	// Update on results
+	// Update the relevant target's submenu and the aggregate icon whenever
+	// any target produces a new sample.
 	go func() {
-		for range a.mgr.Results() {
-			a.updateMenu()
+		for sample := range a.targets.Results() {
+			a.updateTargetMenu(sample.TargetID)
+			a.updateIcon()
 		}
 	}()
 
-	// On entering in-flight, update once using the elapsed age to influence background
+	// In-flight probes don't emit samples, so redraw the icon on a timer
+	// too, to reflect whichever target is currently mid-probe.
 	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond)
+		ticker := time.NewTicker(200 * time.Millisecond)
 		defer ticker.Stop()
 		for range ticker.C {
-			_, age := a.mgr.IsInFlight()
-			a.updateIcon(age.Milliseconds())
+			a.updateIcon()
 		}
 	}()
 }
 
 func (a *TrayApp) onExit() {
-	a.mgr.Stop()
+	a.targets.StopAll()
 }
 
-func (a *TrayApp) updateMenu() {
-	latest := a.mgr.History().Latest(20)
-	// latest returns most recent first; map to menu top→newest
-	for i := 0; i < 20; i++ {
+// addTargetMenu builds the submenu for t and renders its initial state.
+// New samples refresh it through the shared results loop in onReady.
+func (a *TrayApp) addTargetMenu(t config.TargetConfig) {
+	header := systray.AddMenuItem(t.Label, fmt.Sprintf("[%s] %s", t.Probe.Mode, t.Address))
+
+	infoItem := header.AddSubMenuItem(fmt.Sprintf("[%s] %s", t.Probe.Mode, t.Address), "Current address and probe mode")
+	infoItem.Disable()
+
+	statsItem := header.AddSubMenuItem("", "Running latency/loss stats")
+	statsItem.Disable()
+
+	changeAddressItem := header.AddSubMenuItem("Change Address...", "Change this target's address")
+	go func(id string, mi *systray.MenuItem) {
+		for range mi.ClickedCh {
+			a.handleChangeAddress(id)
+		}
+	}(t.ID, changeAddressItem)
+
+	lastHourItem := header.AddSubMenuItem("Last hour", "Notify with stats for the last hour")
+	go func(id string, mi *systray.MenuItem) {
+		for range mi.ClickedCh {
+			a.handleShowStats(id, "Last hour", time.Hour)
+		}
+	}(t.ID, lastHourItem)
+
+	last24hItem := header.AddSubMenuItem("Last 24h", "Notify with stats for the last 24 hours")
+	go func(id string, mi *systray.MenuItem) {
+		for range mi.ClickedCh {
+			a.handleShowStats(id, "Last 24h", 24*time.Hour)
+		}
+	}(t.ID, last24hItem)
+
+	modeItems := make(map[config.ProbeMode]*systray.MenuItem, len(pinger.RegisteredModes()))
+	for _, name := range pinger.RegisteredModes() {
+		mode := config.ProbeMode(name)
+		item := header.AddSubMenuItem(fmt.Sprintf("%s Mode", name), fmt.Sprintf("Use the %s probe", name))
+		if mode == t.Probe.Mode {
+			item.Check()
+		}
+		modeItems[mode] = item
+		go func(id string, mode config.ProbeMode, mi *systray.MenuItem) {
+			for range mi.ClickedCh {
+				a.handleChangeMode(id, mode)
+			}
+		}(t.ID, mode, item)
+	}
+
+	sampleItems := make([]*systray.MenuItem, 20)
+	for i := range sampleItems {
+		item := header.AddSubMenuItem("…", "Ping sample")
+		sampleItems[i] = item
+		go func(mi *systray.MenuItem) {
+			for range mi.ClickedCh {
+				// Selecting a sample closes the popup on macOS; do nothing.
+			}
+		}(item)
+	}
+
+	a.mu.Lock()
+	a.targetMenus[t.ID] = &targetMenu{
+		header:      header,
+		infoItem:    infoItem,
+		statsItem:   statsItem,
+		modeItems:   modeItems,
+		sampleItems: sampleItems,
+	}
+	a.mu.Unlock()
+
+	a.updateTargetMenu(t.ID)
+}
+
+// updateTargetMenu refreshes the submenu for id from its Manager's live state.
+func (a *TrayApp) updateTargetMenu(id string) {
+	a.mu.Lock()
+	tm, ok := a.targetMenus[id]
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+	mgr, ok := a.targets.Get(id)
+	if !ok {
+		return
+	}
+
+	tm.infoItem.SetTitle(fmt.Sprintf("[%s] %s", mgr.ProbeMode(), mgr.Target()))
+	tm.statsItem.SetTitle("1m " + statsLabel(mgr.History().Stats(time.Minute)))
+	for mode, item := range tm.modeItems {
+		if mode == mgr.ProbeMode() {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+
+	latest := mgr.History().Latest(len(tm.sampleItems))
+	for i, item := range tm.sampleItems {
 		label := "—"
 		if i < len(latest) {
 			s := latest[i]
@@ -138,68 +218,168 @@ func (a *TrayApp) updateMenu() {
 				label = fmt.Sprintf("%s  %d ms", s.Timestamp.Format("15:04:05"), s.Latency.Milliseconds())
 			}
 		}
-		a.menuItems[i].SetTitle(label)
+		item.SetTitle(label)
 	}
 }
 
 func (a *TrayApp) updateTooltip() {
-	mode := a.mgr.ProbeMode()
-	target := a.mgr.Target()
-	systray.SetTooltip(fmt.Sprintf("Pinger [%s] → %s", mode, target))
+	systray.SetTooltip(fmt.Sprintf("Pinger (%d targets)", len(a.targets.IDs())))
 }
 
-func (a *TrayApp) updateTargetLabel() {
-	mode := a.mgr.ProbeMode()
-	target := a.mgr.Target()
-	a.targetLabel.SetTitle(fmt.Sprintf("[%s] → %s", mode, target))
+func (a *TrayApp) handleAddTarget() {
+	address := a.showInputDialog("Add Target", "Enter the address or hostname to monitor:", "")
+	if address == "" {
+		return
+	}
+
+	t := a.cfg.AddTarget(address)
+	if err := a.cfg.Save(); err != nil {
+		// Silently fail, but could show notification
+		return
+	}
+
+	a.targets.Add(t.ID, t.Address, t.Interval, t.Timeout, t.Probe, t.History)
+	a.addTargetMenu(t)
+	a.updateTooltip()
 }
 
-func (a *TrayApp) updateModeCheckmarks() {
-	mode := a.mgr.ProbeMode()
-	if mode == config.ProbeModeICMP {
-		a.icmpModeItem.Check()
-		a.httpModeItem.Uncheck()
-	} else {
-		a.icmpModeItem.Uncheck()
-		a.httpModeItem.Check()
+func (a *TrayApp) handleRemoveTarget() {
+	label := a.showInputDialog("Remove Target", "Enter the exact label of the target to remove:", "")
+	if label == "" {
+		return
+	}
+
+	var id string
+	for _, t := range a.cfg.Targets {
+		if t.Label == label {
+			id = t.ID
+			break
+		}
+	}
+	if id == "" {
+		return
+	}
+
+	a.cfg.RemoveTarget(id)
+	if err := a.cfg.Save(); err != nil {
+		return
 	}
+
+	a.targets.Remove(id)
+
+	a.mu.Lock()
+	tm, ok := a.targetMenus[id]
+	delete(a.targetMenus, id)
+	a.mu.Unlock()
+	if ok {
+		tm.header.Hide()
+	}
+
+	a.updateTooltip()
+	a.updateIcon()
 }
 
-func (a *TrayApp) handleChangeTarget() {
-	currentTarget := a.mgr.Target()
-	newTarget := a.showInputDialog("Change Target", "Enter new target address:", currentTarget)
-	if newTarget == "" || newTarget == currentTarget {
+func (a *TrayApp) handleChangeAddress(id string) {
+	mgr, ok := a.targets.Get(id)
+	if !ok {
+		return
+	}
+
+	current := mgr.Target()
+	newAddress := a.showInputDialog("Change Address", "Enter new target address:", current)
+	if newAddress == "" || newAddress == current {
 		return
 	}
 
-	a.cfg.Target = newTarget
+	for i, t := range a.cfg.Targets {
+		if t.ID == id {
+			a.cfg.Targets[i].Address = newAddress
+			break
+		}
+	}
 	if err := a.cfg.Save(); err != nil {
 		// Silently fail, but could show notification
 		return
 	}
 
-	a.mgr.SetTarget(newTarget)
-	a.mgr.Restart()
-	a.updateTooltip()
-	a.updateTargetLabel()
+	mgr.SetTarget(newAddress)
+	mgr.Restart()
+	a.updateTargetMenu(id)
 }
 
-func (a *TrayApp) handleChangeMode(mode config.ProbeMode) {
-	if a.mgr.ProbeMode() == mode {
-		return // Already in this mode
+func (a *TrayApp) handleChangeMode(id string, mode config.ProbeMode) {
+	mgr, ok := a.targets.Get(id)
+	if !ok || mgr.ProbeMode() == mode {
+		return
 	}
 
-	a.cfg.ProbeMode = mode
+	probeCfg := config.DefaultProbeConfig(mode)
+	for i, t := range a.cfg.Targets {
+		if t.ID == id {
+			a.cfg.Targets[i].Probe = probeCfg
+			break
+		}
+	}
 	if err := a.cfg.Save(); err != nil {
 		// Silently fail
 		return
 	}
 
-	a.mgr.SetProbeMode(mode)
-	a.mgr.Restart()
-	a.updateModeCheckmarks()
-	a.updateTooltip()
-	a.updateTargetLabel()
+	mgr.SetProbeConfig(probeCfg)
+	mgr.Restart()
+	a.updateTargetMenu(id)
+}
+
+// handleEditAlerts prompts for a rule spec and, if valid, adds it to both
+// the live Engine and the persisted config.
+func (a *TrayApp) handleEditAlerts() {
+	if a.alerts == nil {
+		return
+	}
+
+	spec := a.showInputDialog("Alerts",
+		`New rule: "latency_above <trigger_us> <clear_us> <samples>", "loss_above <trigger_pct> <clear_pct> <samples>", or "down <consecutive_failures>"`, "")
+	if spec == "" {
+		return
+	}
+
+	rule, err := alert.ParseRuleSpec(spec)
+	if err != nil {
+		a.showNotification("Alerts", err.Error())
+		return
+	}
+
+	a.cfg.Alerts = append(a.cfg.Alerts, rule)
+	if err := a.cfg.Save(); err != nil {
+		a.showNotification("Alerts", "Failed to save rule")
+		return
+	}
+
+	a.alerts.AddRule(rule)
+	a.showNotification("Alerts", fmt.Sprintf("Rule added: %s", rule.Kind))
+}
+
+func (a *TrayApp) handleShowStats(id, label string, window time.Duration) {
+	mgr, ok := a.targets.Get(id)
+	if !ok {
+		return
+	}
+	a.showNotification(mgr.Target(), fmt.Sprintf("%s — %s", label, statsLabel(mgr.History().Stats(window))))
+}
+
+// statsLabel formats stats the way the menubar and notifications show them,
+// e.g. "p95: 42 ms · loss 0.3%".
+func statsLabel(stats pinger.Stats) string {
+	var loss float64
+	if stats.Count > 0 {
+		loss = float64(stats.Failures) / float64(stats.Count) * 100
+	}
+	return fmt.Sprintf("p95: %d ms · loss %.1f%%", stats.P95.Milliseconds(), loss)
+}
+
+func (a *TrayApp) showNotification(title, message string) {
+	script := fmt.Sprintf(`display notification "%s" with title "%s"`, message, title)
+	_ = exec.Command("osascript", "-e", script).Run()
 }
 
 func (a *TrayApp) showInputDialog(title, prompt, defaultValue string) string {
@@ -227,8 +407,25 @@ func (a *TrayApp) showInputDialog(title, prompt, defaultValue string) string {
 	return strings.TrimSpace(parts[1])
 }
 
-func (a *TrayApp) updateIcon(inflightAge int64) {
-	png := renderer.Render(a.mgr.History(), inflightAge)
+func (a *TrayApp) updateIcon() {
+	ids := a.targets.IDs()
+	histories := make([]*pinger.History, 0, len(ids))
+	var inFlight bool
+	for _, id := range ids {
+		if mgr, ok := a.targets.Get(id); ok {
+			histories = append(histories, mgr.History())
+			if flying, _ := mgr.IsInFlight(); flying {
+				inFlight = true
+			}
+		}
+	}
+
+	var firing bool
+	if a.alerts != nil {
+		firing = a.alerts.Firing()
+	}
+
+	png := renderer.RenderTargets(histories, firing, inFlight)
 	a.iconMu.Lock()
 	defer a.iconMu.Unlock()
 	if bytes.Equal(a.lastIcon, png) {
@@ -2,10 +2,12 @@ package main
 
 import (
 	"log"
-	"time"
 
+	"github.com/nenad/pinger/internal/alert"
 	"github.com/nenad/pinger/internal/config"
+	"github.com/nenad/pinger/internal/export"
 	pinger "github.com/nenad/pinger/internal/ping"
+	"github.com/nenad/pinger/internal/store"
 	"github.com/nenad/pinger/internal/ui"
 )
 
@@ -21,12 +23,59 @@ func main() {
 		log.Printf("Warning: Failed to save config: %v", err)
 	}
 
-	// Create ping manager with config values
-	interval := time.Second
-	timeout := 2 * time.Second
-	mgr := pinger.NewManager(cfg.Target, interval, timeout, cfg.ProbeMode, 60)
+	// Start one manager per configured target
+	targets := pinger.NewTargetSet()
+	for _, t := range cfg.Targets {
+		targets.Add(t.ID, t.Address, t.Interval, t.Timeout, t.Probe, t.History)
+	}
+
+	// Persist every sample and let long-window stats queries fall back to it
+	db, err := store.Open()
+	if err != nil {
+		log.Printf("Warning: Failed to open sample store: %v", err)
+	} else {
+		defer db.Close()
+		db.Subscribe(targets.Results())
+		for _, id := range targets.IDs() {
+			if mgr, ok := targets.Get(id); ok {
+				mgr.History().SetLongWindowSource(id, db)
+			}
+		}
+	}
+
+	// Start configured metrics exporters and fan samples out to them
+	var exporters []export.Exporter
+	for _, ec := range cfg.Exporters {
+		switch ec.Kind {
+		case config.ExporterKindPrometheus:
+			e, err := export.NewPrometheusExporter(ec.ListenAddr)
+			if err != nil {
+				log.Printf("Warning: failed to start prometheus exporter: %v", err)
+				continue
+			}
+			exporters = append(exporters, e)
+		case config.ExporterKindStatsD:
+			e, err := export.NewStatsDExporter(ec.Addr, ec.Prefix)
+			if err != nil {
+				log.Printf("Warning: failed to start statsd exporter: %v", err)
+				continue
+			}
+			exporters = append(exporters, e)
+		default:
+			log.Printf("Warning: unknown exporter kind %q", ec.Kind)
+		}
+	}
+	if len(exporters) > 0 {
+		fanout := export.NewFanout(exporters)
+		defer fanout.Close()
+		go fanout.Run(targets.Results())
+	}
+
+	// Evaluate alert rules against every sample and notify configured sinks
+	alertEngine := alert.NewEngineFromConfig(cfg)
+	go alertEngine.Run(targets.Results())
 
 	// Create and run UI
-	app := ui.NewTrayApp(mgr, cfg)
+	app := ui.NewTrayApp(targets, cfg, alertEngine)
 	app.Run()
 }